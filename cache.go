@@ -0,0 +1,110 @@
+package main
+
+import (
+	"container/list"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fileCache is an LRU holding raw file bytes between the extract and
+// rewrite passes, bounded by a byte budget rather than an entry count.
+// A nil *fileCache is valid and simply disables caching. On eviction a
+// file is not reparsed here — the caller just re-reads and re-extracts
+// it on its next access, same as a cache miss.
+type fileCache struct {
+	mu      sync.Mutex
+	limit   int64
+	used    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	path string
+	src  []byte
+}
+
+// newFileCache returns a fileCache capped at limit bytes. A non-positive
+// limit disables caching (Put becomes a no-op).
+func newFileCache(limit int64) *fileCache {
+	return &fileCache{limit: limit, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *fileCache) Get(path string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).src, true
+}
+
+func (c *fileCache) Put(path string, src []byte) {
+	if c == nil || c.limit <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[path]; ok {
+		c.used -= int64(len(el.Value.(*cacheEntry).src))
+		c.order.Remove(el)
+		delete(c.entries, path)
+	}
+
+	size := int64(len(src))
+	if size > c.limit {
+		// Larger than the whole budget: skip it rather than evict
+		// everything else for a file that won't fit anyway.
+		return
+	}
+
+	for c.used+size > c.limit && c.order.Len() > 0 {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.path)
+		c.used -= int64(len(entry.src))
+	}
+
+	el := c.order.PushFront(&cacheEntry{path: path, src: src})
+	c.entries[path] = el
+	c.used += size
+}
+
+// detectMemoryLimit returns a quarter of total system memory in bytes,
+// read from /proc/meminfo, falling back to a fixed default on systems
+// where that file doesn't exist.
+func detectMemoryLimit() int64 {
+	const fallback = 512 << 20 // 512MB
+
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return fallback
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "MemTotal:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			break
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			break
+		}
+		return (kb * 1024) / 4
+	}
+
+	return fallback
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// explainCmd implements `classy explain <short>`: given a short class
+// name found in shipped CSS, it reverse-looks-up which original
+// classes produced it and where they appeared, using a source map
+// previously written by --sourcemap.
+func explainCmd(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	sourcemapPath := fs.String("sourcemap", "classy-sourcemap.json", "path to a source map written by --sourcemap")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Println("usage: classy explain [--sourcemap path] <short>")
+		os.Exit(1)
+	}
+	short := fs.Arg(0)
+
+	sm, err := LoadSourceMap(*sourcemapPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	matches := sm.ReverseLookup(short)
+	if len(matches) == 0 {
+		fmt.Printf("no originals found for %q in %s\n", short, *sourcemapPath)
+		return
+	}
+
+	for _, m := range matches {
+		fmt.Printf("%s -> %s  (%s:%d:%d)\n",
+			strings.Join(m.OriginalClasses, " "), strings.Join(m.RenamedClasses, " "),
+			m.File, m.Line, m.Col)
+	}
+}
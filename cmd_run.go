@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+// runCmd implements `classy run <target>`: it resolves target's
+// inheritance chain from the config file and executes the usual
+// extract/rank/rename pipeline scoped to that target's dir and globs.
+func runCmd(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to classy.yaml/classy.toml (default: search current directory)")
+	preview := fs.Bool("preview", false, "only show class renaming without modifying files")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Println("usage: classy run [--config path] <target>")
+		os.Exit(1)
+	}
+	targetName := fs.Arg(0)
+
+	cfgPath := *configPath
+	if cfgPath == "" {
+		var err error
+		cfgPath, err = findConfigFile(".")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	cfg, err := loadConfig(cfgPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	target, err := cfg.ApplyInherits(targetName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if target.Dir == "" {
+		target.Dir = "."
+	}
+
+	runTarget(target, *preview)
+}
+
+// runTarget executes the pipeline for a single resolved Target.
+func runTarget(t *Target, preview bool) {
+	preserve, err := compilePreserve(t.Preserve)
+	if err != nil {
+		fmt.Printf("Error compiling preserve pattern: %v\n", err)
+		return
+	}
+
+	prior, err := loadManifest(t.ManifestIn)
+	if err != nil {
+		fmt.Printf("Error reading manifest-in: %v\n", err)
+		return
+	}
+
+	opts := DefaultPipelineOptions()
+	matches := func(path string) bool { return targetMatches(t, path) }
+
+	classCount, nonCSSSeen := ExtractPipeline(t.Dir, opts, matches)
+	if t.preserveUnreferencedCSS() {
+		classCount = filterUnreferencedCSS(classCount, nonCSSSeen)
+	}
+
+	classes := rankClasses(classCount, preserve)
+	classMap := generateClassMapFromManifest(classes, prior)
+	if t.Prefix != "" {
+		classMap = prefixClassMap(classMap, t.Prefix, prior)
+	}
+
+	if t.ManifestOut != "" {
+		if err := saveManifest(t.ManifestOut, manifestFromClassMap(classMap)); err != nil {
+			fmt.Printf("Error writing manifest-out: %v\n", err)
+			return
+		}
+	}
+
+	if preview {
+		printClassMap(classMap)
+		return
+	}
+
+	RewritePipeline(t.Dir, classMap, opts, matches)
+}
+
+// targetMatches reports whether path (relative to t.Dir) should be
+// scanned for this target: it must match an include pattern (if any
+// are set) and must not match an exclude pattern.
+func targetMatches(t *Target, path string) bool {
+	rel, err := filepath.Rel(t.Dir, path)
+	if err != nil {
+		rel = path
+	}
+
+	for _, pattern := range t.Exclude {
+		if ok, _ := doublestar.PathMatch(pattern, rel); ok {
+			return false
+		}
+	}
+
+	if len(t.Include) == 0 {
+		return true
+	}
+	for _, pattern := range t.Include {
+		if ok, _ := doublestar.PathMatch(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixClassMap prepends prefix to every newly-allocated short name,
+// leaving the original class names (the map keys) untouched. Entries
+// pulled from prior's manifest already have the prefix baked in from
+// the run that produced them, so re-prefixing those would compound on
+// every subsequent run against the same manifest (px-b, then px-px-b,
+// and so on).
+func prefixClassMap(classMap map[string]string, prefix string, prior *Manifest) map[string]string {
+	prefixed := make(map[string]string, len(classMap))
+	for original, short := range classMap {
+		if prior != nil {
+			if _, found := prior.Map[original]; found {
+				prefixed[original] = short
+				continue
+			}
+		}
+		prefixed[original] = prefix + short
+	}
+	return prefixed
+}
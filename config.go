@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are searched for, in order, when a target is run
+// without an explicit --config path.
+var configFileNames = []string{"classy.yaml", "classy.yml", "classy.toml"}
+
+// Target is one named bundle in a classy.yaml/classy.toml config, e.g.
+// "admin" vs "public" or "email-templates" vs "app". Fields left zero
+// are inherited from the parent named in Inherits.
+type Target struct {
+	Name                    string   `yaml:"-" toml:"-"`
+	Inherits                string   `yaml:"inherits" toml:"inherits"`
+	Dir                     string   `yaml:"dir" toml:"dir"`
+	Include                 []string `yaml:"include" toml:"include"`
+	Exclude                 []string `yaml:"exclude" toml:"exclude"`
+	Preserve                []string `yaml:"preserve" toml:"preserve"`
+	Prefix                  string   `yaml:"prefix" toml:"prefix"`
+	PreserveUnreferencedCSS *bool    `yaml:"preserve_unreferenced_css" toml:"preserve_unreferenced_css"`
+	ManifestIn              string   `yaml:"manifest_in" toml:"manifest_in"`
+	ManifestOut             string   `yaml:"manifest_out" toml:"manifest_out"`
+}
+
+// preserveUnreferencedCSS reports the resolved value of
+// PreserveUnreferencedCSS, defaulting to false when no target in the
+// inherits chain set it explicitly.
+func (t *Target) preserveUnreferencedCSS() bool {
+	return t.PreserveUnreferencedCSS != nil && *t.PreserveUnreferencedCSS
+}
+
+// Config is the top-level shape of a classy.yaml/classy.toml file.
+type Config struct {
+	Targets map[string]*Target `yaml:"targets" toml:"targets"`
+}
+
+// loadConfig reads and unmarshals a classy config, choosing the decoder
+// by extension.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if filepath.Ext(path) == ".toml" {
+		if err := toml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	for name, t := range cfg.Targets {
+		t.Name = name
+	}
+	return &cfg, nil
+}
+
+// findConfigFile looks for a classy config under dir, preferring YAML
+// over TOML when both are present.
+func findConfigFile(dir string) (string, error) {
+	for _, name := range configFileNames {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no classy.yaml or classy.toml found in %s", dir)
+}
+
+// ApplyInherits resolves the full configuration for the named target by
+// walking its `inherits` chain back to the root, then applying each
+// target's settings in root-to-leaf order so a child always overrides
+// its parent. This mirrors Closurer's GSS target-node inheritance.
+func (c *Config) ApplyInherits(name string) (*Target, error) {
+	seen := make(map[string]bool)
+	var chain []*Target
+
+	for name != "" {
+		if seen[name] {
+			return nil, fmt.Errorf("inherits cycle detected at target %q", name)
+		}
+		seen[name] = true
+
+		t, ok := c.Targets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown target %q", name)
+		}
+		chain = append([]*Target{t}, chain...)
+		name = t.Inherits
+	}
+
+	resolved := &Target{}
+	for _, t := range chain {
+		mergeTarget(resolved, t)
+	}
+	return resolved, nil
+}
+
+// mergeTarget layers src's non-zero fields onto dst, so later calls
+// (children) override earlier ones (parents). PreserveUnreferencedCSS
+// is a *bool rather than bool so a child can explicitly set it back to
+// false even though a parent set it to true — a plain bool's zero value
+// can't distinguish "unset" from "explicitly false".
+func mergeTarget(dst, src *Target) {
+	dst.Name = src.Name
+	if src.Dir != "" {
+		dst.Dir = src.Dir
+	}
+	if len(src.Include) > 0 {
+		dst.Include = src.Include
+	}
+	if len(src.Exclude) > 0 {
+		dst.Exclude = src.Exclude
+	}
+	if len(src.Preserve) > 0 {
+		dst.Preserve = src.Preserve
+	}
+	if src.Prefix != "" {
+		dst.Prefix = src.Prefix
+	}
+	if src.PreserveUnreferencedCSS != nil {
+		dst.PreserveUnreferencedCSS = src.PreserveUnreferencedCSS
+	}
+	if src.ManifestIn != "" {
+		dst.ManifestIn = src.ManifestIn
+	}
+	if src.ManifestOut != "" {
+		dst.ManifestOut = src.ManifestOut
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestApplyInheritsLayersChildOverParent(t *testing.T) {
+	cfg := &Config{Targets: map[string]*Target{
+		"base": {Dir: "src", Preserve: []string{"hljs-*"}},
+		"admin": {
+			Inherits: "base",
+			Prefix:   "adm-",
+		},
+	}}
+
+	resolved, err := cfg.ApplyInherits("admin")
+	if err != nil {
+		t.Fatalf("ApplyInherits returned error: %v", err)
+	}
+	if resolved.Dir != "src" {
+		t.Errorf("Dir = %q, want inherited %q", resolved.Dir, "src")
+	}
+	if resolved.Prefix != "adm-" {
+		t.Errorf("Prefix = %q, want child's own %q", resolved.Prefix, "adm-")
+	}
+	if len(resolved.Preserve) != 1 || resolved.Preserve[0] != "hljs-*" {
+		t.Errorf("Preserve = %v, want inherited [hljs-*]", resolved.Preserve)
+	}
+}
+
+func TestApplyInheritsChildOverridesParentDir(t *testing.T) {
+	cfg := &Config{Targets: map[string]*Target{
+		"base":  {Dir: "src"},
+		"child": {Inherits: "base", Dir: "src/admin"},
+	}}
+
+	resolved, err := cfg.ApplyInherits("child")
+	if err != nil {
+		t.Fatalf("ApplyInherits returned error: %v", err)
+	}
+	if resolved.Dir != "src/admin" {
+		t.Errorf("Dir = %q, want child's override %q", resolved.Dir, "src/admin")
+	}
+}
+
+func TestApplyInheritsDetectsCycle(t *testing.T) {
+	cfg := &Config{Targets: map[string]*Target{
+		"a": {Inherits: "b"},
+		"b": {Inherits: "a"},
+	}}
+
+	if _, err := cfg.ApplyInherits("a"); err == nil {
+		t.Error("ApplyInherits did not report the inherits cycle")
+	}
+}
+
+func TestApplyInheritsUnknownTarget(t *testing.T) {
+	cfg := &Config{Targets: map[string]*Target{}}
+	if _, err := cfg.ApplyInherits("missing"); err == nil {
+		t.Error("ApplyInherits did not report the unknown target")
+	}
+}
+
+func TestMergeTargetPreserveUnreferencedCSSChildCanUnset(t *testing.T) {
+	dst := &Target{}
+	mergeTarget(dst, &Target{PreserveUnreferencedCSS: boolPtr(true)})
+	if !dst.preserveUnreferencedCSS() {
+		t.Fatalf("preserveUnreferencedCSS() = false after parent set it true")
+	}
+
+	mergeTarget(dst, &Target{PreserveUnreferencedCSS: boolPtr(false)})
+	if dst.preserveUnreferencedCSS() {
+		t.Errorf("preserveUnreferencedCSS() = true, want child's explicit false to win")
+	}
+}
+
+func TestMergeTargetPreserveUnreferencedCSSUnsetChildInherits(t *testing.T) {
+	dst := &Target{}
+	mergeTarget(dst, &Target{PreserveUnreferencedCSS: boolPtr(true)})
+	// A child that never mentions the field at all must not reset it.
+	mergeTarget(dst, &Target{Prefix: "x-"})
+	if !dst.preserveUnreferencedCSS() {
+		t.Errorf("preserveUnreferencedCSS() = false, want parent's true to carry through an unrelated child override")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "sort"
+
+// Edit describes a single replacement against the original file bytes.
+// Start and End are byte offsets into the original content, [Start, End),
+// and Replacement is the text that should appear in their place.
+type Edit struct {
+	Start       int
+	End         int
+	Replacement string
+}
+
+// Rewriter applies a set of non-overlapping Edits to a file's original
+// bytes, producing the rewritten content in one pass. Implementations
+// must not mutate src.
+type Rewriter interface {
+	Apply(src []byte, edits []Edit) ([]byte, error)
+}
+
+// offsetRewriter is the default Rewriter: it sorts edits by Start and
+// splices them into src once, so a class is never renamed twice and the
+// surrounding formatting is preserved byte-for-byte outside the edited
+// spans.
+type offsetRewriter struct{}
+
+// NewRewriter returns the default offset-based Rewriter.
+func NewRewriter() Rewriter {
+	return offsetRewriter{}
+}
+
+func (offsetRewriter) Apply(src []byte, edits []Edit) ([]byte, error) {
+	if len(edits) == 0 {
+		return src, nil
+	}
+
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	out := make([]byte, 0, len(src))
+	cursor := 0
+	for _, e := range sorted {
+		if e.Start < cursor {
+			// Overlapping edits indicate a bug in the extractor that
+			// produced them; skip rather than corrupt the output.
+			continue
+		}
+		out = append(out, src[cursor:e.Start]...)
+		out = append(out, e.Replacement...)
+		cursor = e.End
+	}
+	out = append(out, src[cursor:]...)
+	return out, nil
+}
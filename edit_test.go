@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestOffsetRewriterApply(t *testing.T) {
+	src := []byte(`<div class="foo bar"></div>`)
+	edits := []Edit{
+		{Start: 12, End: 15, Replacement: "a"},
+		{Start: 16, End: 19, Replacement: "b"},
+	}
+
+	out, err := NewRewriter().Apply(src, edits)
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	want := `<div class="a b"></div>`
+	if string(out) != want {
+		t.Errorf("Apply() = %q, want %q", out, want)
+	}
+}
+
+func TestOffsetRewriterApplyNoEdits(t *testing.T) {
+	src := []byte(`<div class="foo"></div>`)
+
+	out, err := NewRewriter().Apply(src, nil)
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	if string(out) != string(src) {
+		t.Errorf("Apply() = %q, want src unchanged %q", out, src)
+	}
+}
+
+func TestOffsetRewriterApplySkipsOverlapping(t *testing.T) {
+	src := []byte(`foobar`)
+	edits := []Edit{
+		{Start: 0, End: 4, Replacement: "XXXX"},
+		// overlaps the edit above; a well-behaved extractor never
+		// produces this, but the rewriter must not corrupt the output.
+		{Start: 2, End: 6, Replacement: "YYYY"},
+	}
+
+	out, err := NewRewriter().Apply(src, edits)
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	want := `XXXXar`
+	if string(out) != want {
+		t.Errorf("Apply() = %q, want %q", out, want)
+	}
+}
+
+func TestOffsetRewriterApplyUnsortedEdits(t *testing.T) {
+	src := []byte(`a b c`)
+	edits := []Edit{
+		{Start: 4, End: 5, Replacement: "Z"},
+		{Start: 0, End: 1, Replacement: "X"},
+		{Start: 2, End: 3, Replacement: "Y"},
+	}
+
+	out, err := NewRewriter().Apply(src, edits)
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	want := `X Y Z`
+	if string(out) != want {
+		t.Errorf("Apply() = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "path/filepath"
+
+// ClassOccurrence is a single class-name token found in a source file,
+// together with its exact byte span in the original content so a
+// Rewriter can replace it in place.
+type ClassOccurrence struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// ClassExtractor finds every class-name token in a file's raw bytes.
+// Each source format (HTML, CSS, JS, ...) gets its own implementation
+// so processFile and renameClassesInFile can dispatch by extension
+// instead of branching on format inline.
+type ClassExtractor interface {
+	Extract(src []byte) ([]ClassOccurrence, error)
+}
+
+// extractors maps a file extension to the ClassExtractor that
+// understands it. isSupportedFile and extractorFor both read from this
+// table, so adding a format means adding one entry here.
+var extractors = map[string]ClassExtractor{
+	".html":   templateExtractor{},
+	".gohtml": templateExtractor{},
+	".tmpl":   templateExtractor{},
+	".php":    htmlExtractor{},
+	".css":    cssExtractor{},
+	".js":     jsExtractor{},
+	".jsx":    jsxExtractor{},
+	".tsx":    jsxExtractor{},
+	".vue":    vueExtractor{},
+}
+
+// extractorFor returns the ClassExtractor registered for path's
+// extension, or nil if the file isn't supported.
+func extractorFor(path string) ClassExtractor {
+	return extractors[filepath.Ext(path)]
+}
+
+func isSupportedFile(path string) bool {
+	return extractorFor(path) != nil
+}
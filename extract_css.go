@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/tdewolff/parse/css"
+)
+
+// cssExtractor tokenizes selectors with a real CSS tokenizer rather than
+// a single catch-all regex, so compound selectors (`.a.b.c`), `:not(.bar)`,
+// and attribute selectors (`[class~="foo"]`, which are not class
+// selectors at all and must be left alone) are all handled correctly.
+type cssExtractor struct{}
+
+func (cssExtractor) Extract(src []byte) ([]ClassOccurrence, error) {
+	return cssClassOccurrences(src), nil
+}
+
+// cssClassOccurrences tokenizes src as CSS and returns every class
+// selector in it: the identifier immediately following a bare `.`
+// delimiter. Shared with the JS extractor, which runs this over the
+// string argument of a querySelector(All) call rather than a whole
+// stylesheet.
+func cssClassOccurrences(src []byte) []ClassOccurrence {
+	l := css.NewLexer(bytes.NewReader(src))
+
+	var occs []ClassOccurrence
+	offset := 0
+	afterDot := false
+
+	for {
+		tt, data := l.Next()
+		if tt == css.ErrorToken {
+			break
+		}
+
+		if tt == css.DelimToken && string(data) == "." {
+			afterDot = true
+			offset += len(data)
+			continue
+		}
+
+		if afterDot && tt == css.IdentToken {
+			occs = append(occs, ClassOccurrence{
+				Name:  string(data),
+				Start: offset,
+				End:   offset + len(data),
+			})
+		}
+
+		afterDot = false
+		offset += len(data)
+	}
+
+	return occs
+}
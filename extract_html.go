@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+
+	"golang.org/x/net/html"
+)
+
+// htmlExtractor extracts class names from `class="..."` attributes using
+// a real HTML tokenizer instead of a line-oriented regex, so it handles
+// attributes that span multiple lines, are single-quoted, or sit inside
+// self-closing tags.
+type htmlExtractor struct{}
+
+func (htmlExtractor) Extract(src []byte) ([]ClassOccurrence, error) {
+	var occs []ClassOccurrence
+	z := html.NewTokenizer(bytes.NewReader(src))
+	offset := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		raw := z.Raw()
+		tagStart := offset
+		offset += len(raw)
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		_, hasAttr := z.TagName()
+		if !hasAttr {
+			continue
+		}
+
+		cursor := 0
+		for {
+			key, val, more := z.TagAttr()
+			if valStart, ok := attrValuePos(raw, cursor, key, val); ok {
+				if string(key) == "class" {
+					occs = append(occs, fieldsWithOffset(string(val), tagStart+valStart)...)
+				}
+				cursor = valStart + len(val)
+			}
+			if !more {
+				break
+			}
+		}
+	}
+
+	return occs, nil
+}
+
+// attrValuePos locates key's value within raw, searching from cursor
+// onward rather than for the value alone, so an earlier attribute
+// sharing an identical literal value (e.g. `data-info="foo bar"
+// class="foo bar"`) can't be mistaken for the one being looked up. The
+// html tokenizer only hands back decoded key/value pairs, not their
+// byte spans, so each attribute must be re-found in the tag's raw text
+// in the order TagAttr() yields them.
+func attrValuePos(raw []byte, cursor int, key, val []byte) (int, bool) {
+	keyIdx := bytes.Index(raw[cursor:], key)
+	if keyIdx < 0 {
+		return 0, false
+	}
+	searchFrom := cursor + keyIdx + len(key)
+	if len(val) == 0 {
+		return searchFrom, true
+	}
+	valIdx := bytes.Index(raw[searchFrom:], val)
+	if valIdx < 0 {
+		return 0, false
+	}
+	return searchFrom + valIdx, true
+}
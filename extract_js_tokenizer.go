@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tdewolff/parse/js"
+)
+
+// jsExtractor walks a real JS token stream rather than matching whole
+// statements with regexes, so it can see inside constructs the old
+// regexes missed entirely: `clsx('a', cond && 'b')`, chained
+// `classList.toggle(x, cond)` calls, and the static portions of
+// template literals used as a className.
+type jsExtractor struct{}
+
+// classBearingFuncs are bare utility-function calls whose string-literal
+// arguments are treated as class names, regardless of receiver.
+var classBearingFuncs = map[string]bool{
+	"clsx":       true,
+	"classNames": true,
+	"classnames": true,
+}
+
+// classListMethods are classList method calls whose string-literal
+// arguments are class names — but only when the receiver is actually
+// `classList` (`el.classList.add(...)`), not any same-named method on
+// an unrelated object (`cart.add(...)`, `shoppingList.remove(...)`).
+var classListMethods = map[string]bool{
+	"add":    true,
+	"remove": true,
+	"toggle": true,
+}
+
+// selectorFuncs are DOM query calls whose string argument is a CSS
+// selector rather than a bare class name, so its contents are parsed
+// with the CSS tokenizer instead of split on whitespace.
+var selectorFuncs = map[string]bool{
+	"querySelector":    true,
+	"querySelectorAll": true,
+}
+
+// jsCallKind is which class-bearing call (if any) a StringToken or
+// TemplateToken is currently nested inside.
+type jsCallKind int
+
+const (
+	callNone jsCallKind = iota
+	callClass
+	callSelector
+)
+
+// callKindForChain classifies a call by its member-access chain (e.g.
+// ["el", "classList", "add"] for `el.classList.add(...)`), requiring
+// classListMethods to be preceded by an actual classList receiver.
+func callKindForChain(chain []string) jsCallKind {
+	n := len(chain)
+	if n == 0 {
+		return callNone
+	}
+	last := chain[n-1]
+	switch {
+	case classBearingFuncs[last]:
+		return callClass
+	case classListMethods[last] && n >= 2 && chain[n-2] == "classList":
+		return callClass
+	case selectorFuncs[last]:
+		return callSelector
+	}
+	return callNone
+}
+
+func (jsExtractor) Extract(src []byte) ([]ClassOccurrence, error) {
+	l := js.NewLexer(bytes.NewReader(src))
+
+	var occs []ClassOccurrence
+	offset := 0
+	depth := 0
+	activeKind := callNone
+	activeDepth := -1
+	var chain []string
+	prevIdent := ""
+	sawClassName := false
+
+	for {
+		tt, data := l.Next()
+		if tt == js.ErrorToken {
+			break
+		}
+		tokStart := offset
+		offset += len(data)
+
+		switch {
+		case tt == js.IdentifierToken:
+			name := string(data)
+			chain = append(chain, name)
+			if name == "className" {
+				sawClassName = true
+			}
+			prevIdent = name
+
+		case tt == js.PunctuatorToken && string(data) == ".":
+			prevIdent = "."
+
+		case tt == js.PunctuatorToken && string(data) == "(":
+			depth++
+			if activeDepth == -1 {
+				if kind := callKindForChain(chain); kind != callNone {
+					activeKind = kind
+					activeDepth = depth
+				}
+			}
+			chain = nil
+
+		case tt == js.PunctuatorToken && string(data) == ")":
+			if activeDepth == depth {
+				activeDepth = -1
+				activeKind = callNone
+			}
+			depth--
+			chain = nil
+
+		case tt == js.StringToken:
+			switch {
+			case activeKind == callClass:
+				occs = append(occs, stringLiteralClasses(data, tokStart)...)
+			case activeKind == callSelector:
+				occs = append(occs, selectorStringClasses(data, tokStart)...)
+			case sawClassName && prevIdent == "=":
+				occs = append(occs, stringLiteralClasses(data, tokStart)...)
+			}
+			sawClassName = false
+			chain = nil
+
+		case tt == js.TemplateToken:
+			if activeKind == callClass || sawClassName {
+				occs = append(occs, templateLiteralClasses(data, tokStart)...)
+			}
+			sawClassName = false
+			chain = nil
+
+		case tt == js.PunctuatorToken && string(data) == "=":
+			// keep sawClassName alive across the `=`
+			if prevIdent != "className" {
+				sawClassName = false
+			}
+			prevIdent = "="
+			chain = nil
+
+		default:
+			if tt != js.WhitespaceToken && tt != js.SingleLineCommentToken && tt != js.MultiLineCommentToken {
+				prevIdent = ""
+				chain = nil
+			}
+		}
+	}
+
+	return occs, nil
+}
+
+// stringLiteralClasses splits a quoted JS string token into
+// whitespace-separated class names, offsetting each by the token's
+// position (plus one, to skip the opening quote) in the source.
+func stringLiteralClasses(tok []byte, tokStart int) []ClassOccurrence {
+	if len(tok) < 2 {
+		return nil
+	}
+	inner := string(tok[1 : len(tok)-1])
+	return fieldsWithOffset(inner, tokStart+1)
+}
+
+// selectorStringClasses parses a quoted querySelector(All) argument as
+// a CSS selector and returns every class compound in it (".header-box"
+// -> "header-box"), rather than splitting it on whitespace the way a
+// bare class-list string is handled.
+func selectorStringClasses(tok []byte, tokStart int) []ClassOccurrence {
+	if len(tok) < 2 {
+		return nil
+	}
+	inner := tok[1 : len(tok)-1]
+	return offsetOccurrences(cssClassOccurrences(inner), tokStart+1)
+}
+
+// templateLiteralClasses extracts class names from the literal (non
+// `${...}`) portions of a template token, skipping interpolations
+// entirely since their contents aren't statically known.
+func templateLiteralClasses(tok []byte, tokStart int) []ClassOccurrence {
+	s := string(tok)
+	s = strings.TrimPrefix(s, "`")
+	s = strings.TrimSuffix(s, "`")
+	s = strings.TrimSuffix(s, "${")
+	s = strings.TrimPrefix(s, "}")
+
+	var occs []ClassOccurrence
+	base := tokStart + strings.Index(string(tok), s)
+	parts := strings.Split(s, "${")
+	pos := base
+	for i, part := range parts {
+		if i > 0 {
+			// the interpolation expression itself was dropped by Split;
+			// its length can't be recovered here, so later literal
+			// segments are only approximately positioned.
+			pos += len("${")
+		}
+		static := part
+		if idx := strings.Index(static, "}"); i > 0 && idx >= 0 {
+			static = static[idx+1:]
+			pos += idx + 1
+		}
+		occs = append(occs, fieldsWithOffset(static, pos)...)
+		pos += len(part)
+	}
+	return occs
+}
+
+func fieldsWithOffset(s string, base int) []ClassOccurrence {
+	var occs []ClassOccurrence
+	cursor := 0
+	for _, name := range strings.Fields(s) {
+		rel := strings.Index(s[cursor:], name)
+		if rel < 0 {
+			continue
+		}
+		start := base + cursor + rel
+		occs = append(occs, ClassOccurrence{Name: name, Start: start, End: start + len(name)})
+		cursor += rel + len(name)
+	}
+	return occs
+}
@@ -0,0 +1,124 @@
+package main
+
+import "bytes"
+
+// jsxExtractor finds class names inside `className` attributes in
+// JSX/TSX source. The tdewolff JS lexer isn't JSX-aware (it has no
+// notion of `<div>` markup), so rather than feed it the whole file we
+// scan for `className` attributes by hand and only lex the JS
+// expression inside `{...}` with jsExtractor, which keeps this robust
+// against arbitrary surrounding JSX and narrows string/template
+// literals to the same recognized class-bearing calls (clsx,
+// classList.add/remove/toggle) the plain-JS extractor requires, rather
+// than treating every string anywhere in the braces as a class name.
+type jsxExtractor struct{}
+
+func (jsxExtractor) Extract(src []byte) ([]ClassOccurrence, error) {
+	var occs []ClassOccurrence
+	const attr = "className"
+
+	for i := 0; i < len(src); {
+		idx := bytes.Index(src[i:], []byte(attr))
+		if idx < 0 {
+			break
+		}
+		pos := i + idx
+		i = pos + len(attr)
+
+		if pos > 0 && isIdentByte(src[pos-1]) {
+			continue
+		}
+		after := pos + len(attr)
+		if after < len(src) && isIdentByte(src[after]) {
+			continue
+		}
+
+		j := skipSpace(src, after)
+		if j >= len(src) || src[j] != '=' {
+			continue
+		}
+		j = skipSpace(src, j+1)
+		if j >= len(src) {
+			continue
+		}
+
+		switch src[j] {
+		case '"', '\'':
+			quote := src[j]
+			end := j + 1
+			for end < len(src) && src[end] != quote {
+				end++
+			}
+			if end >= len(src) {
+				continue
+			}
+			occs = append(occs, fieldsWithOffset(string(src[j+1:end]), j+1)...)
+			i = end + 1
+
+		case '{':
+			end := matchingBrace(src, j)
+			if end < 0 {
+				continue
+			}
+			sub, err := jsExtractor{}.Extract(src[j+1 : end])
+			if err == nil {
+				occs = append(occs, offsetOccurrences(sub, j+1)...)
+			}
+			i = end + 1
+		}
+	}
+
+	return occs, nil
+}
+
+// isIdentByte reports whether b can appear inside a JS identifier.
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' ||
+		(b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+func skipSpace(src []byte, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// matchingBrace returns the index of the `}` matching the `{` at
+// src[open], skipping over nested braces and string/template literals.
+func matchingBrace(src []byte, open int) int {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'', '`':
+			i = skipJSString(src, i) - 1
+		}
+	}
+	return -1
+}
+
+// skipJSString returns the index just past the closing quote of the
+// string/template literal starting at src[start], honoring backslash
+// escapes.
+func skipJSString(src []byte, start int) int {
+	quote := src[start]
+	i := start + 1
+	for i < len(src) {
+		if src[i] == '\\' {
+			i += 2
+			continue
+		}
+		if src[i] == quote {
+			return i + 1
+		}
+		i++
+	}
+	return len(src)
+}
@@ -0,0 +1,41 @@
+package main
+
+import "bytes"
+
+// templateExtractor handles Go/Hugo-style templates, where `{{ ... }}`
+// actions are interleaved with literal HTML. Actions are treated as
+// opaque: we blank them out in place (preserving byte offsets) and hand
+// the rest to htmlExtractor, so a class inside an action like
+// `{{ if .Foo }}` is correctly left alone while classes in the
+// surrounding markup are still found and renamed.
+type templateExtractor struct{}
+
+func (templateExtractor) Extract(src []byte) ([]ClassOccurrence, error) {
+	return htmlExtractor{}.Extract(blankTemplateActions(src))
+}
+
+// blankTemplateActions returns a copy of src with every `{{ ... }}`
+// action overwritten with spaces, keeping the slice the same length so
+// offsets computed against it stay valid against the original.
+func blankTemplateActions(src []byte) []byte {
+	out := make([]byte, len(src))
+	copy(out, src)
+
+	for i := 0; i < len(out); {
+		if !bytes.HasPrefix(out[i:], []byte("{{")) {
+			i++
+			continue
+		}
+		rel := bytes.Index(out[i+2:], []byte("}}"))
+		if rel < 0 {
+			break
+		}
+		end := i + 2 + rel + 2
+		for k := i; k < end; k++ {
+			out[k] = ' '
+		}
+		i = end
+	}
+
+	return out
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// names returns occ's Name fields in order, for easy comparison against
+// an expected slice.
+func names(occs []ClassOccurrence) []string {
+	out := make([]string, len(occs))
+	for i, occ := range occs {
+		out[i] = occ.Name
+	}
+	return out
+}
+
+// checkOffsets asserts that each occurrence's Start:End span in src
+// actually contains its reported Name.
+func checkOffsets(t *testing.T, src []byte, occs []ClassOccurrence) {
+	t.Helper()
+	for _, occ := range occs {
+		if occ.Start < 0 || occ.End > len(src) || occ.Start > occ.End {
+			t.Errorf("occurrence %q has out-of-range span [%d:%d)", occ.Name, occ.Start, occ.End)
+			continue
+		}
+		if got := string(src[occ.Start:occ.End]); got != occ.Name {
+			t.Errorf("occurrence %q has span [%d:%d) = %q, want it to match the name", occ.Name, occ.Start, occ.End, got)
+		}
+	}
+}
+
+func TestHTMLExtractor(t *testing.T) {
+	src := []byte(`<div class="foo bar"><span class='baz'></span></div>`)
+	occs, err := htmlExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+	if got := names(occs); !reflect.DeepEqual(got, []string{"foo", "bar", "baz"}) {
+		t.Errorf("names = %v, want [foo bar baz]", got)
+	}
+}
+
+func TestHTMLExtractorDuplicateAttrValue(t *testing.T) {
+	// data-info shares class's literal value; the real class attribute
+	// must still be the one whose occurrences are reported.
+	src := []byte(`<div data-info="foo bar" class="foo bar"></div>`)
+	occs, err := htmlExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+
+	wantStart := len(`<div data-info="foo bar" class="`)
+	if len(occs) == 0 || occs[0].Start != wantStart {
+		t.Errorf("first occurrence starts at %d, want %d (inside the class attribute, not data-info)", occs[0].Start, wantStart)
+	}
+}
+
+func TestCSSExtractor(t *testing.T) {
+	src := []byte(`.a.b { color: red } .c:not(.d) { } [class~="e"] { }`)
+	occs, err := cssExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+	if got := names(occs); !reflect.DeepEqual(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("names = %v, want [a b c d] (attribute selector must not match)", got)
+	}
+}
+
+func TestJSExtractorClsxAndClassList(t *testing.T) {
+	src := []byte(`clsx('a', cond && 'b'); el.classList.add('c'); el.classList.toggle('d', on);`)
+	occs, err := jsExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+	if got := names(occs); !reflect.DeepEqual(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("names = %v, want [a b c d]", got)
+	}
+}
+
+func TestJSExtractorIgnoresUnrelatedReceivers(t *testing.T) {
+	src := []byte(`cart.add('discount-code'); shoppingList.remove('milk'); featureFlags.toggle('beta-mode');`)
+	occs, err := jsExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if len(occs) != 0 {
+		t.Errorf("occurrences = %v, want none (add/remove/toggle need a classList receiver)", names(occs))
+	}
+}
+
+func TestJSExtractorQuerySelector(t *testing.T) {
+	src := []byte(`document.querySelector('.header-box'); document.querySelectorAll('.a .b');`)
+	occs, err := jsExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+	if got := names(occs); !reflect.DeepEqual(got, []string{"header-box", "a", "b"}) {
+		t.Errorf("names = %v, want [header-box a b]", got)
+	}
+}
+
+func TestJSExtractorClassNameAssignment(t *testing.T) {
+	src := []byte(`el.className = 'foo bar';`)
+	occs, err := jsExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+	if got := names(occs); !reflect.DeepEqual(got, []string{"foo", "bar"}) {
+		t.Errorf("names = %v, want [foo bar]", got)
+	}
+}
+
+func TestJSXExtractor(t *testing.T) {
+	src := []byte(`<div className="foo bar"><span className={clsx('baz', active && 'qux')} /></div>`)
+	occs, err := jsxExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+	if got := names(occs); !reflect.DeepEqual(got, []string{"foo", "bar", "baz", "qux"}) {
+		t.Errorf("names = %v, want [foo bar baz qux]", got)
+	}
+}
+
+func TestJSXExtractorIgnoresUnrelatedStrings(t *testing.T) {
+	src := []byte(`<div className={i18n.t('some.translation.key')} />`)
+	occs, err := jsxExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	if len(occs) != 0 {
+		t.Errorf("occurrences = %v, want none (i18n.t is not a recognized class-bearing call)", names(occs))
+	}
+}
+
+func TestVueExtractorMultipleBlocksOfSameKind(t *testing.T) {
+	src := []byte(`<template><div class="tpl"></div></template>
+<style scoped>.scoped-only { color: red }</style>
+<style>.global-only { color: blue }</style>
+<script>clsx('script-one')</script>
+<script setup>clsx('script-two')</script>`)
+
+	occs, err := vueExtractor{}.Extract(src)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+	checkOffsets(t, src, occs)
+
+	want := []string{"tpl", "scoped-only", "global-only", "script-one", "script-two"}
+	if got := names(occs); !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v (every block of a given kind must be scanned)", got, want)
+	}
+}
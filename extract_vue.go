@@ -0,0 +1,102 @@
+package main
+
+import "bytes"
+
+// vueExtractor handles single-file components: the <template> block is
+// scanned as HTML, <style> as CSS, and <script> as JS, each offset back
+// into the original file so rewrites land in the right place.
+//
+// <style scoped> blocks are scanned exactly like global ones rather
+// than being namespaced per file. Vue's own compiler already isolates
+// scoped classes by rewriting selectors to include a generated
+// `[data-v-xxxxxxxx]` attribute, so two components using the same
+// literal class name (e.g. both have a `.title`) are already distinct
+// at runtime; renaming `.title` to the same short name everywhere it
+// appears preserves that behavior rather than undermining it.
+type vueExtractor struct{}
+
+func (vueExtractor) Extract(src []byte) ([]ClassOccurrence, error) {
+	var occs []ClassOccurrence
+
+	for _, block := range vueBlocks(src, "template") {
+		sub, err := htmlExtractor{}.Extract(block.content)
+		if err != nil {
+			return nil, err
+		}
+		occs = append(occs, offsetOccurrences(sub, block.start)...)
+	}
+
+	for _, block := range vueBlocks(src, "style") {
+		sub, err := cssExtractor{}.Extract(block.content)
+		if err != nil {
+			return nil, err
+		}
+		occs = append(occs, offsetOccurrences(sub, block.start)...)
+	}
+
+	for _, block := range vueBlocks(src, "script") {
+		sub, err := jsExtractor{}.Extract(block.content)
+		if err != nil {
+			return nil, err
+		}
+		occs = append(occs, offsetOccurrences(sub, block.start)...)
+	}
+
+	return occs, nil
+}
+
+// vueBlock is one <tag ...>...</tag> block found by vueBlocks, holding
+// its content and the byte offset where that content starts in src.
+type vueBlock struct {
+	content []byte
+	start   int
+}
+
+// vueBlocks returns every top-level <tag ...>...</tag> block in src, in
+// source order. Real SFCs commonly have more than one block of the same
+// kind — a <style scoped> alongside a second global <style>, or a
+// <script> alongside <script setup> — and a class appearing only in a
+// later block still needs to be found.
+func vueBlocks(src []byte, tag string) []vueBlock {
+	openTag := []byte("<" + tag)
+	closeTag := []byte("</" + tag + ">")
+
+	var blocks []vueBlock
+	pos := 0
+	for {
+		idx := bytes.Index(src[pos:], openTag)
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+
+		gt := bytes.IndexByte(src[start:], '>')
+		if gt < 0 {
+			break
+		}
+		tagEnd := start + gt + 1
+
+		closeRel := bytes.Index(src[tagEnd:], closeTag)
+		if closeRel < 0 {
+			pos = tagEnd
+			continue
+		}
+		blockEnd := tagEnd + closeRel
+
+		blocks = append(blocks, vueBlock{content: src[tagEnd:blockEnd], start: tagEnd})
+		pos = blockEnd + len(closeTag)
+	}
+
+	return blocks
+}
+
+// offsetOccurrences shifts every occurrence's span by base, for
+// translating offsets found in an extracted sub-block back into the
+// coordinates of the full file.
+func offsetOccurrences(occs []ClassOccurrence, base int) []ClassOccurrence {
+	shifted := make([]ClassOccurrence, len(occs))
+	for i, occ := range occs {
+		shifted[i] = ClassOccurrence{Name: occ.Name, Start: occ.Start + base, End: occ.End + base}
+	}
+	return shifted
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// manifestVersion is the schema version written to the "version" field
+// of every manifest file. Bump it if the format changes in an
+// incompatible way.
+const manifestVersion = 1
+
+// Manifest is the on-disk record of a prior run's class -> short-name
+// assignments. Loading one into generateClassMap lets a later run keep
+// the same shorts for classes it has already seen, which matters when a
+// long-cached CSS bundle on a CDN must not change hashes across deploys.
+type Manifest struct {
+	Version  int               `json:"version"`
+	Map      map[string]string `json:"map"`
+	Reserved []string          `json:"reserved"`
+}
+
+// loadManifest reads a Manifest from path. A missing file is not an
+// error: it just means there is no prior run to seed from.
+func loadManifest(path string) (*Manifest, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.Map == nil {
+		m.Map = make(map[string]string)
+	}
+	return &m, nil
+}
+
+// saveManifest writes m to path as indented JSON.
+func saveManifest(path string, m *Manifest) error {
+	m.Version = manifestVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// reservedShorts returns the set of short names that generateShortClassName
+// must never hand out: everything the manifest declares reserved, plus
+// every short already assigned in a prior run's map.
+func reservedShorts(m *Manifest) map[string]bool {
+	reserved := make(map[string]bool)
+	if m == nil {
+		return reserved
+	}
+	for _, r := range m.Reserved {
+		reserved[r] = true
+	}
+	for _, short := range m.Map {
+		reserved[short] = true
+	}
+	return reserved
+}
+
+// generateClassMapFromManifest builds the original->short class map the
+// same way generateClassMap does, except previously-seen classes keep
+// the short name recorded in prior, and newly allocated shorts skip
+// anything reserved or already in use.
+func generateClassMapFromManifest(classes []ClassUsage, prior *Manifest) map[string]string {
+	reserved := reservedShorts(prior)
+	classMap := make(map[string]string, len(classes))
+
+	next := 0
+	nextShort := func() string {
+		for {
+			short := generateShortClassName(next)
+			next++
+			if !reserved[short] {
+				return short
+			}
+		}
+	}
+
+	for _, class := range classes {
+		if prior != nil {
+			if short, found := prior.Map[class.name]; found {
+				classMap[class.name] = short
+				continue
+			}
+		}
+		short := nextShort()
+		reserved[short] = true
+		classMap[class.name] = short
+	}
+
+	return classMap
+}
+
+// manifestFromClassMap turns a resolved class map into the Manifest
+// shape written to --manifest-out.
+func manifestFromClassMap(classMap map[string]string) *Manifest {
+	m := &Manifest{Version: manifestVersion, Map: make(map[string]string, len(classMap))}
+	for original, short := range classMap {
+		m.Map[original] = short
+	}
+	return m
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestGenerateClassMapFromManifestSeedsFromPrior(t *testing.T) {
+	prior := &Manifest{Map: map[string]string{"foo": "a"}}
+	classes := []ClassUsage{{name: "foo", count: 5}, {name: "bar", count: 3}}
+
+	classMap := generateClassMapFromManifest(classes, prior)
+
+	if classMap["foo"] != "a" {
+		t.Errorf("foo = %q, want the short from the prior manifest (%q)", classMap["foo"], "a")
+	}
+	if classMap["bar"] == "a" || classMap["bar"] == "" {
+		t.Errorf("bar = %q, want a fresh short distinct from the reserved prior short", classMap["bar"])
+	}
+}
+
+func TestGenerateClassMapFromManifestNilPrior(t *testing.T) {
+	classes := []ClassUsage{{name: "foo", count: 1}}
+	classMap := generateClassMapFromManifest(classes, nil)
+	if classMap["foo"] == "" {
+		t.Errorf("foo was not assigned a short name")
+	}
+}
+
+func TestGenerateClassMapFromManifestSkipsReserved(t *testing.T) {
+	// "a" is the first short generateShortClassName would hand out;
+	// reserving it must push the first class to the next one instead.
+	prior := &Manifest{Reserved: []string{"a"}}
+	classes := []ClassUsage{{name: "foo", count: 1}}
+
+	classMap := generateClassMapFromManifest(classes, prior)
+
+	if classMap["foo"] == "a" {
+		t.Errorf("foo = %q, want a short other than the reserved name", classMap["foo"])
+	}
+}
+
+func TestReservedShortsNilManifest(t *testing.T) {
+	if got := reservedShorts(nil); len(got) != 0 {
+		t.Errorf("reservedShorts(nil) = %v, want empty", got)
+	}
+}
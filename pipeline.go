@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// PipelineOptions configures the worker pool used by both the extract
+// and rewrite passes.
+type PipelineOptions struct {
+	// Workers is the number of goroutines processing files concurrently.
+	Workers int
+	// Cache holds parsed file bytes between the two passes, bounded by
+	// byte size. Nil disables caching.
+	Cache *fileCache
+	// StreamThreshold is the file size above which a file bypasses the
+	// cache and is processed line-by-line instead of loaded whole. Zero
+	// or negative disables streaming.
+	StreamThreshold int64
+	// SourceMap, if set, records every rewritten span during
+	// RewritePipeline so --sourcemap can write it out afterwards. Nil
+	// disables source-map recording.
+	SourceMap *SourceMap
+}
+
+// DefaultPipelineOptions returns one worker per CPU, a cache sized to a
+// quarter of system memory, and a 4MB streaming threshold.
+func DefaultPipelineOptions() PipelineOptions {
+	return PipelineOptions{
+		Workers:         runtime.NumCPU(),
+		Cache:           newFileCache(detectMemoryLimit()),
+		StreamThreshold: 4 << 20,
+	}
+}
+
+// fileTally is one worker's partial extraction result, merged into the
+// aggregate counts by a single reducer goroutine.
+type fileTally struct {
+	counts map[string]int
+	nonCSS map[string]bool
+}
+
+// walkSupportedFiles walks dir and streams every supported file path
+// (optionally narrowed by matches) onto the returned channel, closing
+// it once the walk completes.
+func walkSupportedFiles(dir string, matches func(string) bool) <-chan string {
+	paths := make(chan string, 64)
+	go func() {
+		defer close(paths)
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isSupportedFile(path) {
+				return nil
+			}
+			if matches != nil && !matches(path) {
+				return nil
+			}
+			paths <- path
+			return nil
+		})
+	}()
+	return paths
+}
+
+// ExtractPipeline is the first pass: a fixed pool of workers pulls
+// paths off a channel fed by filepath.WalkDir, extracts their classes,
+// and sends a local tally to a single reducer goroutine that merges
+// everything into the returned classCount and nonCSSSeen sets. This
+// keeps peak memory bounded on large monorepos, where holding every
+// file's state through a single unbounded walk does not scale.
+func ExtractPipeline(dir string, opts PipelineOptions, matches func(string) bool) (classCount map[string]int, nonCSSSeen map[string]bool) {
+	paths := walkSupportedFiles(dir, matches)
+	partials := make(chan fileTally, opts.Workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := fileTally{counts: make(map[string]int), nonCSS: make(map[string]bool)}
+			for path := range paths {
+				isCSS := filepath.Ext(path) == ".css"
+				for _, occ := range extractPath(path, opts) {
+					local.counts[occ.Name]++
+					if !isCSS {
+						local.nonCSS[occ.Name] = true
+					}
+				}
+			}
+			partials <- local
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(partials)
+	}()
+
+	classCount = make(map[string]int)
+	nonCSSSeen = make(map[string]bool)
+	for partial := range partials {
+		for name, count := range partial.counts {
+			classCount[name] += count
+		}
+		for name := range partial.nonCSS {
+			nonCSSSeen[name] = true
+		}
+	}
+	return classCount, nonCSSSeen
+}
+
+// RewritePipeline is the second pass: a fixed pool of workers applies
+// classMap to every supported file under dir via the Edit-based
+// Rewriter, writing each result to a temp file before an atomic rename.
+func RewritePipeline(dir string, classMap map[string]string, opts PipelineOptions, matches func(string) bool) {
+	paths := walkSupportedFiles(dir, matches)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				rewritePath(path, classMap, opts)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// extractPath returns path's class occurrences, consulting opts.Cache
+// and falling back to line-by-line streaming for files larger than
+// opts.StreamThreshold.
+func extractPath(path string, opts PipelineOptions) []ClassOccurrence {
+	extractor := extractorFor(path)
+	if extractor == nil {
+		return nil
+	}
+
+	if exceedsStreamThreshold(path, opts) {
+		return extractStreaming(path, extractor)
+	}
+
+	src, err := readCached(path, opts.Cache)
+	if err != nil {
+		return nil
+	}
+
+	occs, err := extractor.Extract(src)
+	if err != nil {
+		return nil
+	}
+	return occs
+}
+
+// rewritePath re-extracts path the same way extractPath does, turns
+// the occurrences present in classMap into Edits, and applies them via
+// the Rewriter.
+func rewritePath(path string, classMap map[string]string, opts PipelineOptions) {
+	extractor := extractorFor(path)
+	if extractor == nil {
+		return
+	}
+
+	if exceedsStreamThreshold(path, opts) {
+		rewriteStreaming(path, extractor, classMap, opts.SourceMap)
+		return
+	}
+
+	src, err := readCached(path, opts.Cache)
+	if err != nil {
+		return
+	}
+
+	occs, err := extractor.Extract(src)
+	if err != nil {
+		return
+	}
+
+	edits := make([]Edit, 0, len(occs))
+	for _, occ := range occs {
+		newName, found := classMap[occ.Name]
+		if !found {
+			continue
+		}
+		edits = append(edits, Edit{Start: occ.Start, End: occ.End, Replacement: newName})
+		if opts.SourceMap != nil {
+			line, col := lineCol(src, occ.Start)
+			opts.SourceMap.Add(path, line, col, occ.Name, newName)
+		}
+	}
+	if len(edits) == 0 {
+		return
+	}
+
+	out, err := NewRewriter().Apply(src, edits)
+	if err != nil {
+		return
+	}
+
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, out, 0644); err != nil {
+		return
+	}
+	os.Rename(tempPath, path)
+}
+
+func exceedsStreamThreshold(path string, opts PipelineOptions) bool {
+	if opts.StreamThreshold <= 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Size() > opts.StreamThreshold
+}
+
+// readCached returns path's bytes from cache if present, otherwise
+// reads the file from disk and populates the cache (if any) for the
+// next pass. If cache pressure has evicted it since, this is exactly a
+// cache miss and the file is simply re-read.
+func readCached(path string, cache *fileCache) ([]byte, error) {
+	if src, ok := cache.Get(path); ok {
+		return src, nil
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cache.Put(path, src)
+	return src, nil
+}
+
+// extractStreaming scans path line-by-line instead of loading it
+// whole, for files too large to be worth caching. Constructs that span
+// multiple lines won't be seen; that's the deliberate tradeoff for
+// keeping memory bounded on very large files.
+func extractStreaming(path string, extractor ClassExtractor) []ClassOccurrence {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var occs []ClassOccurrence
+	offset := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if lineOccs, err := extractor.Extract(line); err == nil {
+			occs = append(occs, offsetOccurrences(lineOccs, offset)...)
+		}
+		offset += len(line) + 1
+	}
+	return occs
+}
+
+// rewriteStreaming mirrors extractStreaming for the rewrite pass,
+// writing each rewritten line to a temp file before the atomic rename.
+func rewriteStreaming(path string, extractor ClassExtractor, classMap map[string]string, sourceMap *SourceMap) {
+	in, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+
+	tempPath := path + ".tmp"
+	out, err := os.Create(tempPath)
+	if err != nil {
+		return
+	}
+
+	writer := bufio.NewWriter(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		rewritten := line
+		if occs, err := extractor.Extract(line); err == nil {
+			edits := make([]Edit, 0, len(occs))
+			for _, occ := range occs {
+				newName, found := classMap[occ.Name]
+				if !found {
+					continue
+				}
+				edits = append(edits, Edit{Start: occ.Start, End: occ.End, Replacement: newName})
+				sourceMap.Add(path, lineNum, occ.Start+1, occ.Name, newName)
+			}
+			if applied, err := NewRewriter().Apply(line, edits); err == nil {
+				rewritten = applied
+			}
+		}
+		writer.Write(rewritten)
+		writer.WriteByte('\n')
+	}
+	writer.Flush()
+	out.Close()
+	os.Rename(tempPath, path)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PreserveSet decides whether a class name must keep its original
+// identifier. Patterns come from the repeatable --preserve flag and a
+// target's `preserve:` config list, and may be a literal name
+// ("container"), a glob ("hljs-*", "fa-*"), or a regex written
+// `/.../ ` ("/^is-/").
+type PreserveSet struct {
+	literals map[string]bool
+	globs    []string
+	regexes  []*regexp.Regexp
+}
+
+// compilePreserve builds a PreserveSet from raw pattern strings.
+func compilePreserve(patterns []string) (*PreserveSet, error) {
+	ps := &PreserveSet{literals: make(map[string]bool)}
+	for _, p := range patterns {
+		switch {
+		case strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") && len(p) > 1:
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, err
+			}
+			ps.regexes = append(ps.regexes, re)
+		case strings.ContainsAny(p, "*?["):
+			ps.globs = append(ps.globs, p)
+		default:
+			ps.literals[p] = true
+		}
+	}
+	return ps, nil
+}
+
+// Matches reports whether name should be preserved (left unrenamed).
+// A nil PreserveSet matches nothing.
+func (ps *PreserveSet) Matches(name string) bool {
+	if ps == nil {
+		return false
+	}
+	if ps.literals[name] {
+		return true
+	}
+	for _, g := range ps.globs {
+		if ok, _ := filepath.Match(g, name); ok {
+			return true
+		}
+	}
+	for _, re := range ps.regexes {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringListFlag accumulates repeated occurrences of a flag (e.g.
+// `--preserve hljs-* --preserve /^is-/`) into a slice.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// SourceMapEntry records one rewritten span: where it was in the
+// original file and what it was renamed from/to, in the spirit of
+// Source Map v3 but specialized to class renames rather than
+// minified-vs-original code positions.
+type SourceMapEntry struct {
+	File            string   `json:"file"`
+	Line            int      `json:"line"`
+	Col             int      `json:"col"`
+	OriginalClasses []string `json:"originalClasses"`
+	RenamedClasses  []string `json:"renamedClasses"`
+}
+
+// SourceMap is the aggregate written by --sourcemap and read back by
+// `classy explain`. It is safe for concurrent use since the rewrite
+// pipeline's workers populate it from multiple goroutines.
+type SourceMap struct {
+	mu      sync.Mutex
+	Entries []SourceMapEntry `json:"entries"`
+}
+
+// NewSourceMap returns an empty SourceMap ready to be shared across the
+// rewrite pipeline's workers.
+func NewSourceMap() *SourceMap {
+	return &SourceMap{}
+}
+
+// Add records one rewritten class occurrence. A nil SourceMap is valid
+// and simply discards the entry, so callers can pass opts.SourceMap
+// unconditionally.
+func (sm *SourceMap) Add(file string, line, col int, original, renamed string) {
+	if sm == nil {
+		return
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.Entries = append(sm.Entries, SourceMapEntry{
+		File:            file,
+		Line:            line,
+		Col:             col,
+		OriginalClasses: []string{original},
+		RenamedClasses:  []string{renamed},
+	})
+}
+
+// Save writes sm as indented JSON to path.
+func (sm *SourceMap) Save(path string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	data, err := json.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadSourceMap reads a SourceMap previously written by Save.
+func LoadSourceMap(path string) (*SourceMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sm SourceMap
+	if err := json.Unmarshal(data, &sm); err != nil {
+		return nil, err
+	}
+	return &sm, nil
+}
+
+// ReverseLookup returns every entry that renamed a class to short, so
+// `classy explain a7` can show which original names and locations
+// produced it.
+func (sm *SourceMap) ReverseLookup(short string) []SourceMapEntry {
+	var matches []SourceMapEntry
+	for _, e := range sm.Entries {
+		for _, renamed := range e.RenamedClasses {
+			if renamed == short {
+				matches = append(matches, e)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// lineCol computes the 1-based line and column of a byte offset into
+// src, the way most editors and Source Map consumers expect.
+func lineCol(src []byte, offset int) (line, col int) {
+	line, col = 1, 1
+	if offset > len(src) {
+		offset = len(src)
+	}
+	for i := 0; i < offset; i++ {
+		if src[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}